@@ -0,0 +1,17 @@
+// Package auth resolves go-git transport.AuthMethod credentials for
+// carcosa remotes, from anonymous access to SSH keys/agents and HTTPS
+// tokens, with per-host selection so a single carcosa process can talk to
+// several remotes that each need different credentials.
+package auth
+
+import (
+	git_transport "gopkg.in/src-d/go-git.v4/plumbing/transport"
+)
+
+// Auth resolves credentials for a given remote URL. It is called once per
+// remote operation (clone, pull, push), so implementations that need to
+// prompt the user or hit an external service (SSH agent, keyring) should
+// cache their result internally where that makes sense.
+type Auth interface {
+	Get(url string) (git_transport.AuthMethod, error)
+}
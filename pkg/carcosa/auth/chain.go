@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"sync"
+
+	"github.com/reconquest/karma-go"
+	git_transport "gopkg.in/src-d/go-git.v4/plumbing/transport"
+)
+
+// Chain tries a list of Auth providers in order and caches whichever one
+// first succeeds for a given remote URL, so repeated operations against
+// the same remote (pull then push, say) don't re-run every method.
+type Chain struct {
+	Methods []Auth
+
+	mutex sync.Mutex
+	cache map[string]Auth
+}
+
+func (chain *Chain) Get(url string) (git_transport.AuthMethod, error) {
+	chain.mutex.Lock()
+	cached, ok := chain.cache[url]
+	chain.mutex.Unlock()
+
+	if ok {
+		return cached.Get(url)
+	}
+
+	var lastErr error
+
+	for _, method := range chain.Methods {
+		auth, err := method.Get(url)
+		if err != nil {
+			lastErr = err
+
+			continue
+		}
+
+		chain.mutex.Lock()
+		if chain.cache == nil {
+			chain.cache = map[string]Auth{}
+		}
+		chain.cache[url] = method
+		chain.mutex.Unlock()
+
+		return auth, nil
+	}
+
+	return nil, karma.Format(
+		lastErr,
+		"no auth method in chain succeeded for %q", url,
+	)
+}
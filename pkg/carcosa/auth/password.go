@@ -0,0 +1,19 @@
+package auth
+
+import (
+	git_transport "gopkg.in/src-d/go-git.v4/plumbing/transport"
+	git_http "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+)
+
+// Password resolves plain username+password HTTPS credentials.
+type Password struct {
+	Username string
+	Password string
+}
+
+func (auth Password) Get(url string) (git_transport.AuthMethod, error) {
+	return &git_http.BasicAuth{
+		Username: auth.Username,
+		Password: auth.Password,
+	}, nil
+}
@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"strings"
+
+	git_transport "gopkg.in/src-d/go-git.v4/plumbing/transport"
+)
+
+// Resolver picks an Auth provider for a remote URL, first by host-pattern
+// (so a user can configure distinct credentials per host), then by URL
+// scheme (git@ / ssh://, https://, http://), falling back to Default.
+type Resolver struct {
+	// Hosts maps a host pattern (an exact hostname, or a "*.example.com"
+	// suffix wildcard) to the Auth provider to use for it.
+	Hosts map[string]Auth
+
+	// SSH, HTTPS and HTTP are the fallback providers used when no host
+	// pattern matches, selected by URL scheme.
+	SSH   Auth
+	HTTPS Auth
+	HTTP  Auth
+
+	// Default is used when neither a host pattern nor a scheme-based
+	// provider applies.
+	Default Auth
+}
+
+func (resolver Resolver) Get(url string) (git_transport.AuthMethod, error) {
+	if auth := resolver.byHost(url); auth != nil {
+		return auth.Get(url)
+	}
+
+	if auth := resolver.byScheme(url); auth != nil {
+		return auth.Get(url)
+	}
+
+	if resolver.Default != nil {
+		return resolver.Default.Get(url)
+	}
+
+	return Anonymous{}.Get(url)
+}
+
+func (resolver Resolver) byHost(url string) Auth {
+	host := host(url)
+	if host == "" {
+		return nil
+	}
+
+	for pattern, auth := range resolver.Hosts {
+		if hostMatches(pattern, host) {
+			return auth
+		}
+	}
+
+	return nil
+}
+
+func (resolver Resolver) byScheme(url string) Auth {
+	switch {
+	case strings.HasPrefix(url, "git@"), strings.HasPrefix(url, "ssh://"):
+		return resolver.SSH
+	case strings.HasPrefix(url, "https://"):
+		return resolver.HTTPS
+	case strings.HasPrefix(url, "http://"):
+		return resolver.HTTP
+	default:
+		return nil
+	}
+}
+
+func hostMatches(pattern, host string) bool {
+	if pattern == host {
+		return true
+	}
+
+	if strings.HasPrefix(pattern, "*.") {
+		return strings.HasSuffix(host, pattern[1:])
+	}
+
+	return false
+}
+
+// host extracts the hostname from either an HTTP(S) URL or a scp-like SSH
+// URL (git@host:path).
+func host(url string) string {
+	switch {
+	case strings.HasPrefix(url, "https://"), strings.HasPrefix(url, "http://"):
+		rest := url[strings.Index(url, "://")+3:]
+
+		rest = strings.TrimPrefix(rest, stripUserinfo(rest))
+
+		if idx := strings.IndexAny(rest, "/:"); idx != -1 {
+			rest = rest[:idx]
+		}
+
+		return rest
+
+	case strings.HasPrefix(url, "ssh://"):
+		rest := url[len("ssh://"):]
+
+		if idx := strings.Index(rest, "@"); idx != -1 {
+			rest = rest[idx+1:]
+		}
+
+		if idx := strings.IndexAny(rest, "/:"); idx != -1 {
+			rest = rest[:idx]
+		}
+
+		return rest
+
+	case strings.HasPrefix(url, "git@"):
+		rest := url[len("git@"):]
+
+		if idx := strings.Index(rest, ":"); idx != -1 {
+			rest = rest[:idx]
+		}
+
+		return rest
+
+	default:
+		return ""
+	}
+}
+
+func stripUserinfo(rest string) string {
+	if idx := strings.Index(rest, "@"); idx != -1 {
+		if slash := strings.Index(rest, "/"); slash == -1 || idx < slash {
+			return rest[:idx+1]
+		}
+	}
+
+	return ""
+}
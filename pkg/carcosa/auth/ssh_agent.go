@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"os"
+
+	"github.com/reconquest/karma-go"
+	git_transport "gopkg.in/src-d/go-git.v4/plumbing/transport"
+	git_ssh "gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+)
+
+// SSHAgent resolves credentials from a running ssh-agent, connected to via
+// the SSH_AUTH_SOCK environment variable. User defaults to the OS user the
+// process is running as if left empty.
+type SSHAgent struct {
+	User string
+}
+
+func (auth SSHAgent) Get(url string) (git_transport.AuthMethod, error) {
+	if os.Getenv("SSH_AUTH_SOCK") == "" {
+		return nil, karma.Format(
+			nil,
+			"SSH_AUTH_SOCK is not set, ssh-agent is not available",
+		)
+	}
+
+	user := auth.User
+	if user == "" {
+		user = defaultSSHUser()
+	}
+
+	method, err := git_ssh.NewSSHAgentAuth(user)
+	if err != nil {
+		return nil, karma.Describe("user", user).Format(
+			err,
+			"unable to connect to ssh-agent",
+		)
+	}
+
+	return method, nil
+}
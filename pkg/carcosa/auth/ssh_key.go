@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"os"
+	"os/user"
+
+	"github.com/reconquest/karma-go"
+	git_transport "gopkg.in/src-d/go-git.v4/plumbing/transport"
+	git_ssh "gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+)
+
+// SSHKey resolves credentials from an explicit SSH private key file, with
+// an optional passphrase for encrypted keys.
+type SSHKey struct {
+	User       string
+	File       string
+	Passphrase string
+}
+
+func (auth SSHKey) Get(url string) (git_transport.AuthMethod, error) {
+	user := auth.User
+	if user == "" {
+		user = defaultSSHUser()
+	}
+
+	method, err := git_ssh.NewPublicKeysFromFile(user, auth.File, auth.Passphrase)
+	if err != nil {
+		return nil, karma.
+			Describe("user", user).
+			Describe("file", auth.File).
+			Format(
+				err,
+				"unable to load ssh private key",
+			)
+	}
+
+	return method, nil
+}
+
+func defaultSSHUser() string {
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+
+	current, err := user.Current()
+	if err != nil {
+		return "git"
+	}
+
+	return current.Username
+}
@@ -0,0 +1,26 @@
+package auth
+
+import (
+	git_transport "gopkg.in/src-d/go-git.v4/plumbing/transport"
+	git_http "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+)
+
+// Token resolves an HTTPS personal access token, sent as the password of a
+// basic-auth request with Username as the login (most git hosts accept any
+// non-empty username alongside a token, e.g. "x-access-token").
+type Token struct {
+	Username string
+	Token    string
+}
+
+func (auth Token) Get(url string) (git_transport.AuthMethod, error) {
+	username := auth.Username
+	if username == "" {
+		username = "token"
+	}
+
+	return &git_http.BasicAuth{
+		Username: username,
+		Password: auth.Token,
+	}, nil
+}
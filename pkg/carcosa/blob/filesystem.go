@@ -0,0 +1,66 @@
+package blob
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/reconquest/karma-go"
+)
+
+// filesystemStorage stores blobs as plain files on the local filesystem,
+// named after their content hash. It is mainly useful for testing the
+// pointer-blob plumbing without standing up a real object store.
+type filesystemStorage struct {
+	root string
+}
+
+func newFilesystemStorage(root string) (Storage, error) {
+	err := os.MkdirAll(root, 0700)
+	if err != nil {
+		return nil, karma.Describe("root", root).Format(
+			err,
+			"unable to create filesystem blob storage directory",
+		)
+	}
+
+	return &filesystemStorage{root: root}, nil
+}
+
+func (storage *filesystemStorage) Put(hash string, data []byte) error {
+	err := ValidateHash(hash)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(storage.root, hash)
+
+	err = ioutil.WriteFile(path, data, 0600)
+	if err != nil {
+		return karma.Describe("path", path).Format(
+			err,
+			"unable to write blob",
+		)
+	}
+
+	return nil
+}
+
+func (storage *filesystemStorage) Get(hash string) ([]byte, error) {
+	err := ValidateHash(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(storage.root, hash)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, karma.Describe("path", path).Format(
+			err,
+			"unable to read blob",
+		)
+	}
+
+	return data, nil
+}
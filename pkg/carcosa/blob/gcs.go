@@ -0,0 +1,99 @@
+package blob
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/reconquest/karma-go"
+)
+
+// gcsStorage stores blobs as objects in a Google Cloud Storage bucket,
+// addressed as gs://bucket/prefix.
+type gcsStorage struct {
+	client *storage.Client
+	bucket string
+	prefix string
+}
+
+func newGCSStorage(parsed *url.URL) (Storage, error) {
+	client, err := storage.NewClient(context.Background())
+	if err != nil {
+		return nil, karma.Format(
+			err,
+			"unable to create gcs client",
+		)
+	}
+
+	return &gcsStorage{
+		client: client,
+		bucket: parsed.Host,
+		prefix: strings.Trim(parsed.Path, "/"),
+	}, nil
+}
+
+func (storage_ *gcsStorage) Put(hash string, data []byte) error {
+	ctx := context.Background()
+
+	writer := storage_.client.
+		Bucket(storage_.bucket).
+		Object(objectKey(storage_.prefix, hash)).
+		NewWriter(ctx)
+
+	_, err := writer.Write(data)
+	if err != nil {
+		writer.Close()
+
+		return karma.
+			Describe("bucket", storage_.bucket).
+			Describe("hash", hash).
+			Format(
+				err,
+				"unable to write object to gcs",
+			)
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return karma.
+			Describe("bucket", storage_.bucket).
+			Describe("hash", hash).
+			Format(
+				err,
+				"unable to finalize object write to gcs",
+			)
+	}
+
+	return nil
+}
+
+func (storage_ *gcsStorage) Get(hash string) ([]byte, error) {
+	ctx := context.Background()
+
+	reader, err := storage_.client.
+		Bucket(storage_.bucket).
+		Object(objectKey(storage_.prefix, hash)).
+		NewReader(ctx)
+	if err != nil {
+		return nil, karma.
+			Describe("bucket", storage_.bucket).
+			Describe("hash", hash).
+			Format(
+				err,
+				"unable to open object reader from gcs",
+			)
+	}
+	defer reader.Close()
+
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, karma.Format(
+			err,
+			"unable to read object body from gcs",
+		)
+	}
+
+	return data, nil
+}
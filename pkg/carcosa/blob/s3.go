@@ -0,0 +1,83 @@
+package blob
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/reconquest/karma-go"
+)
+
+// s3Storage stores blobs as objects in an Amazon S3 (or S3-compatible)
+// bucket, addressed as s3://bucket/prefix.
+type s3Storage struct {
+	client *s3.S3
+	bucket string
+	prefix string
+}
+
+func newS3Storage(parsed *url.URL) (Storage, error) {
+	sess, err := session.NewSession()
+	if err != nil {
+		return nil, karma.Format(
+			err,
+			"unable to create aws session",
+		)
+	}
+
+	return &s3Storage{
+		client: s3.New(sess),
+		bucket: parsed.Host,
+		prefix: strings.Trim(parsed.Path, "/"),
+	}, nil
+}
+
+func (storage *s3Storage) Put(hash string, data []byte) error {
+	_, err := storage.client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(storage.bucket),
+		Key:    aws.String(objectKey(storage.prefix, hash)),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		return karma.
+			Describe("bucket", storage.bucket).
+			Describe("hash", hash).
+			Format(
+				err,
+				"unable to put object to s3",
+			)
+	}
+
+	return nil
+}
+
+func (storage *s3Storage) Get(hash string) ([]byte, error) {
+	object, err := storage.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(storage.bucket),
+		Key:    aws.String(objectKey(storage.prefix, hash)),
+	})
+	if err != nil {
+		return nil, karma.
+			Describe("bucket", storage.bucket).
+			Describe("hash", hash).
+			Format(
+				err,
+				"unable to get object from s3",
+			)
+	}
+	defer object.Body.Close()
+
+	data, err := ioutil.ReadAll(object.Body)
+	if err != nil {
+		return nil, karma.Format(
+			err,
+			"unable to read object body from s3",
+		)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,144 @@
+// Package blob implements pluggable external storage for large encrypted
+// secret payloads, so the carcosa git repository itself does not have to
+// carry every ciphertext blob inline.
+package blob
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/reconquest/karma-go"
+)
+
+// Magic is the header written at the start of a git blob to mark it as a
+// pointer to externally-stored data rather than inline ciphertext, so that
+// repos mixing inline and external secrets can be read unambiguously.
+const Magic = "carcosa-blob-pointer-v1\n"
+
+// Storage is implemented by every external backend a secret payload can be
+// offloaded to.
+type Storage interface {
+	// Put stores data under the given content hash, overwriting any
+	// existing object with the same hash.
+	Put(hash string, data []byte) error
+
+	// Get retrieves previously stored data by its content hash.
+	Get(hash string) ([]byte, error)
+}
+
+// Pointer is the JSON body committed to git in place of the ciphertext
+// itself, once a secret is large enough to be offloaded to a Storage
+// backend.
+type Pointer struct {
+	Backend string `json:"backend"`
+	Hash    string `json:"hash"`
+	Size    int    `json:"size"`
+}
+
+// Encode marshals a pointer blob, prefixed with Magic so repo.cat() can
+// distinguish it from inline ciphertext.
+func (pointer Pointer) Encode() ([]byte, error) {
+	body, err := json.Marshal(pointer)
+	if err != nil {
+		return nil, karma.Format(
+			err,
+			"unable to marshal blob pointer",
+		)
+	}
+
+	return append([]byte(Magic), body...), nil
+}
+
+// Decode recognizes a pointer blob produced by Encode and returns false if
+// data does not carry the pointer magic header, i.e. it is inline
+// ciphertext.
+func Decode(data []byte) (Pointer, bool, error) {
+	if !strings.HasPrefix(string(data), Magic) {
+		return Pointer{}, false, nil
+	}
+
+	var pointer Pointer
+
+	err := json.Unmarshal(data[len(Magic):], &pointer)
+	if err != nil {
+		return Pointer{}, true, karma.Format(
+			err,
+			"unable to unmarshal blob pointer",
+		)
+	}
+
+	return pointer, true, nil
+}
+
+// New selects a Storage implementation by the URL scheme: `file://` for
+// the local filesystem, `s3://` for Amazon S3 (or S3-compatible)
+// endpoints, and `gs://` for Google Cloud Storage. An empty raw URL means
+// no external storage is configured, in which case New returns a nil
+// Storage and a nil error, and callers should keep storing ciphertext
+// inline in git.
+func New(raw string) (Storage, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return nil, karma.Describe("url", raw).Format(
+			err,
+			"unable to parse blob storage url",
+		)
+	}
+
+	switch parsed.Scheme {
+	case "file":
+		return newFilesystemStorage(parsed.Path)
+	case "s3":
+		return newS3Storage(parsed)
+	case "gs":
+		return newGCSStorage(parsed)
+	default:
+		return nil, karma.Describe("scheme", parsed.Scheme).Format(
+			nil,
+			"unsupported blob storage url %q", raw,
+		)
+	}
+}
+
+func objectKey(prefix, hash string) string {
+	if prefix == "" {
+		return hash
+	}
+
+	return fmt.Sprintf("%s/%s", strings.Trim(prefix, "/"), hash)
+}
+
+// ValidateHash checks that hash is a well-formed hex sha256 digest (64 hex
+// characters; upper-case hex is accepted as well as the lower-case form
+// that write() passes to Put()).
+// Callers must run any Pointer.Hash read back from a (potentially
+// untrusted) git blob through this before using it as a filesystem path
+// or object storage key, since a hash that isn't a plain hex digest could
+// otherwise be used to smuggle path separators or ".." traversal into a
+// Storage backend.
+func ValidateHash(hash string) error {
+	if len(hash) != 64 {
+		return karma.Describe("hash", hash).Format(
+			nil,
+			"invalid blob hash: expected 64 hex characters, got %d",
+			len(hash),
+		)
+	}
+
+	_, err := hex.DecodeString(hash)
+	if err != nil {
+		return karma.Describe("hash", hash).Format(
+			err,
+			"invalid blob hash: not a hex string",
+		)
+	}
+
+	return nil
+}
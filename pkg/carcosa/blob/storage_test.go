@@ -0,0 +1,66 @@
+package blob
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPointerEncodeDecodeRoundTrip(t *testing.T) {
+	pointer := Pointer{
+		Backend: "file:///var/carcosa/blobs",
+		Hash:    strings.Repeat("a", 64),
+		Size:    1234,
+	}
+
+	encoded, err := pointer.Encode()
+	if err != nil {
+		t.Fatalf("unexpected error encoding pointer: %s", err)
+	}
+
+	if !strings.HasPrefix(string(encoded), Magic) {
+		t.Fatalf("expected encoded pointer to start with the magic header")
+	}
+
+	decoded, ok, err := Decode(encoded)
+	if err != nil {
+		t.Fatalf("unexpected error decoding pointer: %s", err)
+	}
+
+	if !ok {
+		t.Fatalf("expected Decode to recognize a pointer blob")
+	}
+
+	if decoded != pointer {
+		t.Fatalf("expected decoded pointer %+v to equal original %+v", decoded, pointer)
+	}
+}
+
+func TestDecodeRejectsInlineCiphertext(t *testing.T) {
+	_, ok, err := Decode([]byte("definitely not a pointer blob"))
+	if err != nil {
+		t.Fatalf("unexpected error decoding inline data: %s", err)
+	}
+
+	if ok {
+		t.Fatalf("expected Decode to report ok == false for inline ciphertext")
+	}
+}
+
+func TestValidateHash(t *testing.T) {
+	if err := ValidateHash(strings.Repeat("a", 64)); err != nil {
+		t.Fatalf("expected a well-formed 64-char hex hash to validate, got: %s", err)
+	}
+
+	cases := []string{
+		"",
+		"../../etc/passwd",
+		strings.Repeat("a", 63),
+		strings.Repeat("z", 64),
+	}
+
+	for _, hash := range cases {
+		if err := ValidateHash(hash); err == nil {
+			t.Fatalf("expected hash %q to be rejected", hash)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package carcosa
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/reconquest/karma-go"
+	git "gopkg.in/src-d/go-git.v4"
+	git_config "gopkg.in/src-d/go-git.v4/config"
+	git_plumbing "gopkg.in/src-d/go-git.v4/plumbing"
+	git_transport "gopkg.in/src-d/go-git.v4/plumbing/transport"
+)
+
+// Clock is a Lamport logical clock, used to establish a causal order
+// between concurrent edits to the "same" secret made independently on
+// different machines (carcosa stores each edit as its own ref, so there
+// is otherwise no inherent order between them). The approach mirrors the
+// per-namespace logical clocks git-bug maintains for its own entities.
+type Clock struct {
+	tick uint64
+}
+
+// Tick returns the clock's current value without advancing it.
+func (clock *Clock) Tick() uint64 {
+	return clock.tick
+}
+
+// Increment advances the clock by one and returns the new value, to be
+// stamped onto whatever event is about to be recorded.
+func (clock *Clock) Increment() uint64 {
+	clock.tick++
+
+	return clock.tick
+}
+
+// Witness advances the clock so that it is guaranteed to be strictly
+// greater than a tick observed from elsewhere (e.g. a remote we just
+// pulled from), per the standard Lamport clock rule.
+func (clock *Clock) Witness(remote uint64) {
+	if remote >= clock.tick {
+		clock.tick = remote + 1
+	}
+}
+
+// Root namespaces the clock subsystem keeps its own bookkeeping refs
+// under, entirely separate from carcosa's secret refspecs. list() filters
+// these out explicitly (on top of the fact that they don't naturally
+// collide with any secret namespace), so a clock or tick ref can never be
+// mistaken for a secret.
+const (
+	clocksRoot       = "refs/carcosa-clocks/"
+	clocksRemoteRoot = "refs/carcosa-clocks-remote/"
+	ticksRoot        = "refs/carcosa-ticks/"
+)
+
+// isBookkeepingRef reports whether name belongs to the clock subsystem
+// rather than being a secret ref list() should ever surface.
+func isBookkeepingRef(name string) bool {
+	return strings.HasPrefix(name, clocksRoot) ||
+		strings.HasPrefix(name, clocksRemoteRoot) ||
+		strings.HasPrefix(name, ticksRoot)
+}
+
+func clockRefName(ns string) string {
+	return fmt.Sprintf("%s%s", clocksRoot, ns)
+}
+
+// clock loads the persisted Lamport clock for ns, returning a fresh clock
+// at zero if none has been stored yet.
+func (repo *repo) clock(ns string) (*Clock, error) {
+	name := clockRefName(ns)
+
+	reference, err := repo.git.Reference(git_plumbing.ReferenceName(name), true)
+	if err != nil {
+		if err == git_plumbing.ErrReferenceNotFound {
+			return &Clock{}, nil
+		}
+
+		return nil, karma.Describe("ref", name).Format(
+			err,
+			"unable to resolve clock reference",
+		)
+	}
+
+	data, err := repo.catLocked(reference.Hash().String())
+	if err != nil {
+		return nil, karma.Describe("ref", name).Format(
+			err,
+			"unable to read clock blob",
+		)
+	}
+
+	tick, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return nil, karma.Describe("ref", name).Format(
+			err,
+			"unable to parse clock value %q", string(data),
+		)
+	}
+
+	return &Clock{tick: tick}, nil
+}
+
+// saveClock persists clock as the current value for ns. Callers are
+// expected to hold the repo's fslock (see lock/unlock) for the duration
+// of the surrounding read-increment-write cycle, so that two carcosa
+// processes on the same host can't race each other onto the same tick.
+func (repo *repo) saveClock(ns string, clock *Clock) error {
+	hash, err := repo.writeInline([]byte(strconv.FormatUint(clock.tick, 10)))
+	if err != nil {
+		return karma.Describe("ns", ns).Format(
+			err,
+			"unable to write clock blob",
+		)
+	}
+
+	name := clockRefName(ns)
+
+	err = repo.git.Storer.SetReference(
+		git_plumbing.NewReferenceFromStrings(name, hash),
+	)
+	if err != nil {
+		return karma.Describe("ref", name).Format(
+			err,
+			"unable to update clock reference",
+		)
+	}
+
+	return nil
+}
+
+// witnessClock advances and persists the clock for ns after observing a
+// remote tick (typically during pull), so that local ticks created
+// afterwards stay causally ordered after it.
+func (repo *repo) witnessClock(ns string, remote uint64) error {
+	err := repo.lock()
+	if err != nil {
+		return err
+	}
+	defer repo.unlock()
+
+	clock, err := repo.clock(ns)
+	if err != nil {
+		return err
+	}
+
+	clock.Witness(remote)
+
+	return repo.saveClock(ns, clock)
+}
+
+// tickRefName derives the name of the out-of-band ref that carries a
+// secret ref's Lamport tick, so the tick travels alongside the secret
+// through push/pull without having to be unpacked from its (encrypted)
+// payload. It lives under its own "refs/carcosa-ticks/" root rather than
+// as a same-namespace sibling of the secret ref (e.g. "<name>.tick"),
+// specifically so it never shares a prefix with any namespace list()
+// might be asked for and doesn't show up as a bogus extra secret.
+func tickRefName(name string) string {
+	return "refs/carcosa-ticks/" + strings.TrimPrefix(name, "refs/")
+}
+
+// writeTick records tick as the sibling ref for a secret ref.
+func (repo *repo) writeTick(name string, tick uint64) error {
+	hash, err := repo.writeInline([]byte(strconv.FormatUint(tick, 10)))
+	if err != nil {
+		return karma.Describe("ref", name).Format(
+			err,
+			"unable to write tick blob",
+		)
+	}
+
+	tickName := tickRefName(name)
+
+	err = repo.git.Storer.SetReference(
+		git_plumbing.NewReferenceFromStrings(tickName, hash),
+	)
+	if err != nil {
+		return karma.Describe("ref", tickName).Format(
+			err,
+			"unable to update tick reference",
+		)
+	}
+
+	return nil
+}
+
+// deleteTick removes the tick ref sibling to a deleted secret ref, so
+// deleting a secret doesn't leave a permanent zombie tick ref behind. A
+// missing tick ref (e.g. for a secret predating this subsystem) is not an
+// error.
+func (repo *repo) deleteTick(name string) error {
+	tickName := tickRefName(name)
+
+	err := repo.git.Storer.RemoveReference(git_plumbing.ReferenceName(tickName))
+	if err != nil && err != git_plumbing.ErrReferenceNotFound {
+		return karma.Describe("ref", tickName).Format(
+			err,
+			"unable to delete tick reference",
+		)
+	}
+
+	return nil
+}
+
+// readTick resolves the Lamport tick recorded for a secret ref, returning
+// 0 if the ref predates this subsystem (so older refs simply sort last,
+// per CompareTicks).
+func (repo *repo) readTick(name string) (uint64, error) {
+	tickName := tickRefName(name)
+
+	reference, err := repo.git.Reference(git_plumbing.ReferenceName(tickName), true)
+	if err != nil {
+		if err == git_plumbing.ErrReferenceNotFound {
+			return 0, nil
+		}
+
+		return 0, karma.Describe("ref", tickName).Format(
+			err,
+			"unable to resolve tick reference",
+		)
+	}
+
+	data, err := repo.catLocked(reference.Hash().String())
+	if err != nil {
+		return 0, karma.Describe("ref", tickName).Format(
+			err,
+			"unable to read tick blob",
+		)
+	}
+
+	tick, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, karma.Describe("ref", tickName).Format(
+			err,
+			"unable to parse tick value %q", string(data),
+		)
+	}
+
+	return tick, nil
+}
+
+// pullClock fetches the remote's Lamport clock for ns into a scratch ref
+// (rather than clobbering the local clock ref directly) and witnesses it,
+// so the local clock ends up strictly ahead of whatever was observed on
+// the remote.
+func (repo *repo) pullClock(
+	remote string,
+	ns string,
+	method git_transport.AuthMethod,
+) error {
+	remoteRef := clockRefName(ns)
+	scratchRef := clocksRemoteRoot + ns
+
+	err := repo.git.Fetch(&git.FetchOptions{
+		Auth:       method,
+		RemoteName: remote,
+		RefSpecs: []git_config.RefSpec{
+			git_config.RefSpec(fmt.Sprintf("+%s:%s", remoteRef, scratchRef)),
+		},
+	})
+	switch err {
+	case nil, git.NoErrAlreadyUpToDate:
+	case git_transport.ErrEmptyRemoteRepository:
+		return nil
+	default:
+		return karma.Describe("ns", ns).Format(
+			err,
+			"unable to fetch remote clock",
+		)
+	}
+
+	reference, err := repo.git.Reference(
+		git_plumbing.ReferenceName(scratchRef), true,
+	)
+	if err != nil {
+		if err == git_plumbing.ErrReferenceNotFound {
+			return nil
+		}
+
+		return karma.Describe("ref", scratchRef).Format(
+			err,
+			"unable to resolve remote clock reference",
+		)
+	}
+
+	data, err := repo.catLocked(reference.Hash().String())
+	if err != nil {
+		return karma.Describe("ref", scratchRef).Format(
+			err,
+			"unable to read remote clock blob",
+		)
+	}
+
+	remoteTick, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return karma.Describe("ref", scratchRef).Format(
+			err,
+			"unable to parse remote clock value %q", string(data),
+		)
+	}
+
+	return repo.witnessClock(ns, remoteTick)
+}
+
+// CompareTicks reports whether the ref identified by (aTick, aHash) should
+// win over (bTick, bHash) when list() finds two refs that decrypt to the
+// same logical secret: the higher tick wins, with ties broken by
+// lexicographic hash order so the choice stays deterministic across
+// machines.
+func CompareTicks(aTick uint64, aHash string, bTick uint64, bHash string) bool {
+	if aTick != bTick {
+		return aTick > bTick
+	}
+
+	return aHash > bHash
+}
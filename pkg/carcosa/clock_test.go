@@ -0,0 +1,58 @@
+package carcosa
+
+import "testing"
+
+func TestClockIncrement(t *testing.T) {
+	clock := &Clock{}
+
+	if clock.Tick() != 0 {
+		t.Fatalf("expected fresh clock to start at 0, got %d", clock.Tick())
+	}
+
+	if tick := clock.Increment(); tick != 1 {
+		t.Fatalf("expected first increment to return 1, got %d", tick)
+	}
+
+	if tick := clock.Increment(); tick != 2 {
+		t.Fatalf("expected second increment to return 2, got %d", tick)
+	}
+}
+
+func TestClockWitnessAdvancesPastRemote(t *testing.T) {
+	clock := &Clock{tick: 5}
+
+	clock.Witness(3)
+	if clock.Tick() != 5 {
+		t.Fatalf("witnessing a lower tick must not move the clock backwards, got %d", clock.Tick())
+	}
+
+	clock.Witness(5)
+	if clock.Tick() != 6 {
+		t.Fatalf("witnessing the current tick must strictly advance the clock, got %d", clock.Tick())
+	}
+
+	clock.Witness(10)
+	if clock.Tick() != 11 {
+		t.Fatalf("witnessing a higher tick must land one past it, got %d", clock.Tick())
+	}
+}
+
+func TestCompareTicksHigherWins(t *testing.T) {
+	if !CompareTicks(2, "aaaa", 1, "bbbb") {
+		t.Fatalf("expected the higher tick to win regardless of hash")
+	}
+
+	if CompareTicks(1, "aaaa", 2, "bbbb") {
+		t.Fatalf("expected the lower tick to lose regardless of hash")
+	}
+}
+
+func TestCompareTicksBreaksTiesByHash(t *testing.T) {
+	if !CompareTicks(1, "bbbb", 1, "aaaa") {
+		t.Fatalf("expected ties to be broken by the lexicographically greater hash")
+	}
+
+	if CompareTicks(1, "aaaa", 1, "bbbb") {
+		t.Fatalf("expected the lexicographically lesser hash to lose a tie")
+	}
+}
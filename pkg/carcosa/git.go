@@ -1,36 +1,111 @@
 package carcosa
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/juju/fslock"
 	"github.com/reconquest/karma-go"
 	"github.com/seletskiy/carcosa/pkg/carcosa/auth"
+	"github.com/seletskiy/carcosa/pkg/carcosa/blob"
+	"github.com/seletskiy/carcosa/pkg/carcosa/keyring"
+	"github.com/seletskiy/carcosa/pkg/carcosa/lfs"
 
 	git "gopkg.in/src-d/go-git.v4"
 	git_config "gopkg.in/src-d/go-git.v4/config"
 	git_plumbing "gopkg.in/src-d/go-git.v4/plumbing"
 	git_transport "gopkg.in/src-d/go-git.v4/plumbing/transport"
+	git_http "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
 )
 
 type repo struct {
 	path string
 	git  *git.Repository
 
+	shallow CloneOptions
+
+	storage    blob.Storage
+	storageURL string
+
+	keyring keyring.Keyring
+
+	lfs       CarcosaLFSConfig
+	lfsAuth   *git_http.BasicAuth
+	lfsRemote string
+
+	// mu guards repo against concurrent use from multiple goroutines in
+	// the same process (go-git's object storer and packfile index are
+	// documented as not safe for concurrent access). list() and cat()
+	// take a read lock, since go-git itself serializes writes internally
+	// and concurrent reads are safe; every mutating git operation (update,
+	// delete, write, writeMany, pull, push) takes a full write lock. The
+	// UseStorage/UseKeyring/UseLFS configuration setters take no lock.
+	// This is separate from the fslock-based mutex below, which instead
+	// guards against concurrent *processes*.
+	mu sync.RWMutex
+
 	mutex struct {
 		path   string
 		handle *fslock.Lock
 	}
 }
 
+// CloneOptions controls how a repository is fetched from its remote, so
+// that callers dealing with large shared repositories (or running in CI)
+// are not forced to pull the full history of unrelated refs.
+//
+// Depth mirrors git.CloneOptions.Depth / git.FetchOptions.Depth: 0 means a
+// full clone/fetch, any positive value requests a shallow history of that
+// many commits.
+//
+// RefSpec overrides the refspec used for the initial fetch (defaults to
+// the carcosa namespace, e.g. "refs/carcosa/*"), so only secrets-related
+// refs are transferred instead of every ref the remote advertises.
+type CloneOptions struct {
+	Depth   int
+	RefSpec string
+}
+
+func (opts CloneOptions) shallow() bool {
+	return opts.Depth > 0
+}
+
+// CarcosaLFSConfig turns on Git LFS offloading for oversized secret
+// payloads, per-repository. Payloads over Threshold bytes are uploaded to
+// the LFS server and replaced in git by a standard LFS pointer blob,
+// mirroring how a real `git-lfs` smudge/clean filter would handle a large
+// tracked file.
+type CarcosaLFSConfig struct {
+	Enable    bool
+	Threshold int
+
+	// Endpoint overrides the LFS server root; when empty it defaults to
+	// the remote's URL with "/info/lfs" appended, as git-lfs itself does.
+	Endpoint string
+}
+
+func (config CarcosaLFSConfig) applies(size int) bool {
+	return config.Enable && size > config.Threshold
+}
+
+// initialize creates a brand new carcosa repository at path, pointed at
+// remote/url for the given namespace. If kr is non-nil it is attached to
+// the returned repo for future passphrase lookups, and if passphrase is
+// non-empty it is remembered under the keyring right away, so that the
+// passphrase the caller just had the user type in doesn't have to be
+// re-entered on the very next operation against this remote/ns.
 func initialize(
 	path string,
 	remote string,
 	url string,
 	ns string,
+	kr keyring.Keyring,
+	passphrase string,
 ) (*repo, error) {
 	log.Infof("{init} %s (%s: %s)", path, remote, url)
 
@@ -58,60 +133,168 @@ func initialize(
 		)
 	}
 
-	return &repo{
+	result := &repo{
 		path: path,
 		git:  git,
-	}, nil
+	}
+
+	if kr != nil {
+		result.UseKeyring(kr)
+	}
+
+	if passphrase != "" {
+		err = result.rememberPassphrase(remote, ns, passphrase)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
 }
 
+// clone fetches an existing carcosa namespace from url into a freshly
+// initialized repository at path. kr and passphrase are handled exactly
+// as in initialize: kr is attached for future lookups, and a non-empty
+// passphrase is remembered immediately after this first successful use.
 func clone(
 	url string,
 	remote string,
 	path string,
+	ns string,
+	opts CloneOptions,
 	auth auth.Auth,
+	kr keyring.Keyring,
+	passphrase string,
 ) (*repo, error) {
 	method, err := auth.Get(url)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Infof("{clone} %s -> %s", url, path)
+	log.Infof(
+		"{clone} %s -> %s (depth: %d)",
+		url, path, opts.Depth,
+	)
 
-	git, err := git.PlainClone(path, false, &git.CloneOptions{
-		NoCheckout: true,
-		RemoteName: remote,
-		Auth:       method,
-		URL:        url,
+	facts := karma.
+		Describe("url", url).
+		Describe("path", path)
+
+	spec := opts.RefSpec
+	if spec == "" {
+		spec = refspec(ns).to()
+	}
+
+	gitRepo, err := git.PlainInit(path, false)
+	if err != nil {
+		return nil, facts.Format(
+			err,
+			"unable to init git repository",
+		)
+	}
+
+	_, err = gitRepo.CreateRemote(&git_config.RemoteConfig{
+		URLs:  []string{url},
+		Name:  remote,
+		Fetch: []git_config.RefSpec{git_config.RefSpec(spec)},
 	})
 	if err != nil {
-		return nil, karma.Format(
+		return nil, facts.Describe("remote", remote).Format(
 			err,
-			"unable to clone git repository %q to %q", url, path,
+			"unable to create remote",
 		)
 	}
 
-	return &repo{
-		path: path,
-		git:  git,
-	}, nil
+	err = gitRepo.Fetch(&git.FetchOptions{
+		Auth:       method,
+		RemoteName: remote,
+		Depth:      opts.Depth,
+		Tags:       git.NoTags,
+	})
+	switch err {
+	case nil, git.NoErrAlreadyUpToDate:
+	case git_transport.ErrEmptyRemoteRepository:
+		log.Infof("{clone} remote repository is empty")
+	default:
+		return nil, facts.Describe("remote", remote).Format(
+			err,
+			"unable to fetch remote",
+		)
+	}
+
+	result := &repo{
+		path:    path,
+		git:     gitRepo,
+		shallow: opts,
+	}
+
+	if kr != nil {
+		result.UseKeyring(kr)
+	}
+
+	if passphrase != "" {
+		err = result.rememberPassphrase(remote, ns, passphrase)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
 }
 
-func open(path string) (*repo, error) {
+// open opens an existing carcosa repository at path. If kr is non-nil it
+// is attached to the returned repo and consulted right away for a
+// previously-remembered passphrase for remote/ns, so the caller can skip
+// prompting when one is found; the resolved passphrase (or "" if none is
+// stored, or kr is nil) is returned as the second value.
+func open(path string, remote string, ns string, kr keyring.Keyring) (*repo, string, error) {
 	git, err := git.PlainOpen(path)
 	if err != nil {
-		return nil, karma.Format(err, "unable to open git repository %q", path)
+		return nil, "", karma.Format(err, "unable to open git repository %q", path)
 	}
 
-	return &repo{
+	result := &repo{
 		path: path,
 		git:  git,
-	}, nil
+	}
+
+	if kr == nil {
+		return result, "", nil
+	}
+
+	result.UseKeyring(kr)
+
+	passphrase, _, err := result.passphrase(remote, ns)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return result, passphrase, nil
 }
 
-func (repo *repo) update(ref ref) error {
+// update sets ref to point at its hash and stamps it with the next tick of
+// the ns Lamport clock, so that if another ref for the same logical secret
+// shows up later (e.g. after a pull from a machine that edited it
+// concurrently), the two can be ordered causally instead of arbitrarily.
+// The ref is only ever written once the fslock is held, so a process that
+// loses the race for the lock fails before mutating anything, instead of
+// leaving behind a ref with no matching tick.
+//
+// Safe for concurrent use: update takes repo's write lock for its
+// duration.
+func (repo *repo) update(ref ref, ns string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
 	log.Debugf("{update} %s > %s", ref.hash, ref.name)
 
-	err := repo.git.Storer.SetReference(
+	err := repo.lock()
+	if err != nil {
+		return err
+	}
+	defer repo.unlock()
+
+	err = repo.git.Storer.SetReference(
 		git_plumbing.NewReferenceFromStrings(ref.name, ref.hash),
 	)
 	if err != nil {
@@ -123,10 +306,27 @@ func (repo *repo) update(ref ref) error {
 		)
 	}
 
-	return nil
+	clock, err := repo.clock(ns)
+	if err != nil {
+		return err
+	}
+
+	tick := clock.Increment()
+
+	err = repo.saveClock(ns, clock)
+	if err != nil {
+		return err
+	}
+
+	return repo.writeTick(ref.name, tick)
 }
 
+// Safe for concurrent use: delete takes repo's write lock for its
+// duration.
 func (repo *repo) delete(ref ref) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
 	log.Tracef("{delete} %s - %s", ref.hash, ref.name)
 
 	err := repo.git.Storer.RemoveReference(
@@ -140,16 +340,197 @@ func (repo *repo) delete(ref ref) error {
 		)
 	}
 
+	return repo.deleteTick(ref.name)
+}
+
+// UseStorage configures an external blob.Storage backend (selected by
+// URL scheme, e.g. "s3://bucket/prefix", "gs://bucket/prefix", or
+// "file:///path") for subsequent write() calls. Passing an empty url
+// disables external storage, so write() goes back to storing ciphertext
+// inline in git. Existing inline blobs remain readable either way, since
+// cat() detects pointer blobs by their magic header rather than relying
+// on this setting.
+func (repo *repo) UseStorage(url string) error {
+	storage, err := blob.New(url)
+	if err != nil {
+		return err
+	}
+
+	repo.storage = storage
+	repo.storageURL = url
+
 	return nil
 }
 
+// UseKeyring configures an OS keyring backend consulted by passphrase()
+// and updated by rememberPassphrase(), so repeat operations against a
+// remote don't require the passphrase to be re-entered every time.
+func (repo *repo) UseKeyring(kr keyring.Keyring) {
+	repo.keyring = kr
+}
+
+// passphrase looks up a previously-remembered passphrase for the given
+// remote/namespace pair. The bool return is false whenever no keyring is
+// configured or no passphrase has been stored yet, in which case the
+// caller should fall back to prompting.
+func (repo *repo) passphrase(remote, ns string) (string, bool, error) {
+	if repo.keyring == nil {
+		return "", false, nil
+	}
+
+	url, err := repo.remoteURL(remote)
+	if err != nil {
+		return "", false, err
+	}
+
+	value, err := repo.keyring.Get(url, ns)
+	if err != nil {
+		return "", false, err
+	}
+
+	return value, value != "", nil
+}
+
+// rememberPassphrase stores a passphrase just entered by the user, keyed
+// by remote URL and namespace, so future operations can skip the prompt.
+// It is a no-op when no keyring backend is configured.
+func (repo *repo) rememberPassphrase(remote, ns, passphrase string) error {
+	if repo.keyring == nil {
+		return nil
+	}
+
+	url, err := repo.remoteURL(remote)
+	if err != nil {
+		return err
+	}
+
+	return repo.keyring.Set(url, ns, passphrase)
+}
+
+// UseLFS turns on Git LFS offloading for subsequent write() calls.
+// remote/auth identify which remote's LFS server to talk to and which
+// credentials to present, reusing the same resolution carcosa already
+// does for push/pull; only the HTTPS basic-auth methods produced by
+// auth.Auth (token or password) are currently supported for LFS, since the
+// batch API is plain HTTPS rather than the git smart-HTTP/SSH protocols.
+func (repo *repo) UseLFS(config CarcosaLFSConfig, remote string, auth auth.Auth) error {
+	repo.lfs = config
+	repo.lfsRemote = remote
+
+	if !config.Enable {
+		return nil
+	}
+
+	if config.Endpoint == "" {
+		url, err := repo.remoteURL(remote)
+		if err != nil {
+			return err
+		}
+
+		repo.lfs.Endpoint = strings.TrimSuffix(url, "/") + "/info/lfs"
+	}
+
+	method, err := repo.auth(remote, auth)
+	if err != nil {
+		return err
+	}
+
+	if method != nil {
+		basic, ok := method.(*git_http.BasicAuth)
+		if !ok {
+			return karma.Describe("method", method).Format(
+				nil,
+				"lfs only supports https token/password auth, not %T", method,
+			)
+		}
+
+		repo.lfsAuth = basic
+	}
+
+	return nil
+}
+
+func (repo *repo) lfsClient() *lfs.Client {
+	return &lfs.Client{
+		Endpoint: repo.lfs.Endpoint,
+		Auth:     repo.lfsAuth,
+	}
+}
+
 func (repo *repo) write(data []byte) (string, error) {
-	var blob git_plumbing.MemoryObject
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.writeLocked(data)
+}
+
+// writeMany writes several objects while holding repo's write lock only
+// once, instead of once per object, so storing many secrets at once avoids
+// the lock-acquisition overhead of an equivalent sequence of write() calls.
+// It does not batch the objects into a single pack; each one is still
+// stored as its own loose object via the underlying storer.
+//
+// Safe for concurrent use: writeMany takes repo's write lock for its
+// duration.
+func (repo *repo) writeMany(datas [][]byte) ([]string, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	hashes := make([]string, len(datas))
+
+	for i, data := range datas {
+		hash, err := repo.writeLocked(data)
+		if err != nil {
+			return nil, karma.Describe("index", i).Format(
+				err,
+				"unable to write object %d of %d",
+				i+1,
+				len(datas),
+			)
+		}
+
+		hashes[i] = hash
+	}
+
+	return hashes, nil
+}
 
-	blob.SetType(git_plumbing.BlobObject)
-	blob.Write(data)
+// writeLocked is the shared implementation of write()/writeMany(); callers
+// must already hold repo.mu for writing.
+func (repo *repo) writeLocked(data []byte) (string, error) {
+	if repo.lfs.applies(len(data)) {
+		return repo.writeLFS(data)
+	}
 
-	hash, err := repo.git.Storer.SetEncodedObject(&blob)
+	if repo.storage != nil {
+		return repo.writeExternal(data)
+	}
+
+	return repo.writeInline(data)
+}
+
+// writeLFS uploads data to the configured LFS server and commits only its
+// pointer blob to git.
+func (repo *repo) writeLFS(data []byte) (string, error) {
+	pointer, err := repo.lfsClient().Upload(data)
+	if err != nil {
+		return "", karma.Describe("endpoint", repo.lfs.Endpoint).Format(
+			err,
+			"unable to upload blob to lfs server (len=%d)",
+			len(data),
+		)
+	}
+
+	return repo.writeInline(pointer.Encode())
+}
+
+func (repo *repo) writeInline(data []byte) (string, error) {
+	var object git_plumbing.MemoryObject
+
+	object.SetType(git_plumbing.BlobObject)
+	object.Write(data)
+
+	hash, err := repo.git.Storer.SetEncodedObject(&object)
 	if err != nil {
 		return "", karma.Format(
 			err,
@@ -161,7 +542,48 @@ func (repo *repo) write(data []byte) (string, error) {
 	return hash.String(), nil
 }
 
+// writeExternal puts the ciphertext into the configured blob.Storage
+// backend and commits only a small JSON pointer blob to git, so large
+// secrets don't bloat the repository itself.
+func (repo *repo) writeExternal(data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	err := repo.storage.Put(hash, data)
+	if err != nil {
+		return "", karma.Describe("hash", hash).Format(
+			err,
+			"unable to put blob to external storage (len=%d)",
+			len(data),
+		)
+	}
+
+	pointer, err := blob.Pointer{
+		Backend: repo.storageURL,
+		Hash:    hash,
+		Size:    len(data),
+	}.Encode()
+	if err != nil {
+		return "", err
+	}
+
+	return repo.writeInline(pointer)
+}
+
+// Safe for concurrent use: list takes repo's read lock for its duration,
+// so it may run concurrently with other list/cat calls but not with a
+// mutating operation.
+//
+// Refs under ns that share a logical secret identifier (everything in the
+// ref name up to its last path component, e.g. two concurrent edits of
+// "refs/carcosa/<ns>/<key>/<edit-a>" and ".../<key>/<edit-b>") are
+// resolved to a single winner via CompareTicks, so concurrent edits made
+// from different machines converge instead of both showing up as distinct
+// secrets.
 func (repo *repo) list(ns string) (refs, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
 	log.Tracef("{list} %s ?", ns)
 
 	list, err := repo.git.References()
@@ -172,40 +594,124 @@ func (repo *repo) list(ns string) (refs, error) {
 		)
 	}
 
-	var refs refs
+	var collected refs
 
 	defer list.Close()
-	defer func() { log.Tracef("{list} %s = %d refs", ns, len(refs)) }()
 
-	return refs, list.ForEach(
+	err = list.ForEach(
 		func(reference *git_plumbing.Reference) error {
 			ref := ref{
 				name: reference.Name().String(),
 				hash: reference.Hash().String(),
 			}
 
+			if isBookkeepingRef(ref.name) {
+				return nil
+			}
+
 			if !strings.HasPrefix(ref.name, ns) {
 				return nil
 			}
 
-			refs = append(refs, ref)
+			collected = append(collected, ref)
 
 			return nil
 		},
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := repo.resolveDuplicates(collected)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Tracef("{list} %s = %d refs", ns, len(refs))
+
+	return refs, nil
+}
+
+// logicalSecretKey derives the logical secret a ref belongs to: its name
+// with the last path component (the specific edit/revision) stripped, so
+// every concurrent edit of the same secret maps to the same key.
+func logicalSecretKey(name string) string {
+	idx := strings.LastIndex(name, "/")
+	if idx == -1 {
+		return name
+	}
+
+	return name[:idx]
+}
+
+// resolveDuplicates groups refs by logicalSecretKey and, for any key with
+// more than one ref, keeps only the winner per CompareTicks (higher
+// Lamport tick wins, ties broken by hash). Groups of one pass through
+// unchanged.
+func (repo *repo) resolveDuplicates(collected refs) (refs, error) {
+	order := make([]string, 0, len(collected))
+	groups := make(map[string]refs, len(collected))
+
+	for _, ref := range collected {
+		key := logicalSecretKey(ref.name)
+
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+
+		groups[key] = append(groups[key], ref)
+	}
+
+	resolved := make(refs, 0, len(order))
+
+	for _, key := range order {
+		group := groups[key]
+
+		winner := group[0]
+
+		if len(group) > 1 {
+			winnerTick, err := repo.readTick(winner.name)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, candidate := range group[1:] {
+				candidateTick, err := repo.readTick(candidate.name)
+				if err != nil {
+					return nil, err
+				}
+
+				if CompareTicks(candidateTick, candidate.hash, winnerTick, winner.hash) {
+					winner = candidate
+					winnerTick = candidateTick
+				}
+			}
+		}
+
+		resolved = append(resolved, winner)
+	}
+
+	return resolved, nil
+}
+
+func (repo *repo) remoteURL(name string) (string, error) {
+	remote, err := repo.git.Remote(name)
+	if err != nil {
+		return "", err
+	}
+
+	return remote.Config().URLs[0], nil
 }
 
 func (repo *repo) auth(
 	name string,
 	auth auth.Auth,
 ) (git_transport.AuthMethod, error) {
-	remote, err := repo.git.Remote(name)
+	url, err := repo.remoteURL(name)
 	if err != nil {
 		return nil, err
 	}
 
-	url := remote.Config().URLs[0]
-
 	log.Debugf("{auth} remote %q | url %q", name, url)
 
 	method, err := auth.Get(url)
@@ -216,8 +722,16 @@ func (repo *repo) auth(
 	return method, nil
 }
 
-func (repo *repo) pull(name string, spec refspec, auth auth.Auth) error {
-	log.Debugf("{pull} %s %s", name, spec.to())
+// Safe for concurrent use: pull takes repo's write lock for its duration,
+// since it mutates repo.git.Storer both directly (via Fetch) and through
+// the clock subsystem.
+func (repo *repo) pull(name string, ns string, spec refspec, auth auth.Auth) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	log.Debugf(
+		"{pull} %s %s (depth: %d)", name, spec.to(), repo.shallow.Depth,
+	)
 
 	method, err := repo.auth(name, auth)
 	if err != nil {
@@ -228,12 +742,11 @@ func (repo *repo) pull(name string, spec refspec, auth auth.Auth) error {
 		Auth:       method,
 		RemoteName: name,
 		RefSpecs:   []git_config.RefSpec{git_config.RefSpec(spec.to())},
+		Depth:      repo.shallow.Depth,
 	})
 	switch err {
 	case nil:
-		return nil
 	case git.NoErrAlreadyUpToDate:
-		return nil
 	case git_transport.ErrEmptyRemoteRepository:
 		log.Infof("{pull} remote repository is empty")
 		return nil
@@ -244,11 +757,50 @@ func (repo *repo) pull(name string, spec refspec, auth auth.Auth) error {
 			name,
 		)
 	}
+
+	err = repo.pullClock(name, ns, method)
+	if err != nil {
+		return karma.Format(
+			err,
+			"unable to synchronize logical clock for namespace %q",
+			ns,
+		)
+	}
+
+	return nil
 }
 
+// push publishes the namespace refspec to the given remote. Note that
+// go-git (unlike the git CLI) has no notion of unshallowing a repository:
+// pushing from a repo cloned with CloneOptions.Depth > 0 can fail against
+// remotes that reject pushes from shallow history, or silently omit
+// objects the remote needs. Callers that need to push from a shallow
+// clone should re-clone with Depth == 0 first; we surface this as a
+// clear error rather than letting go-git fail obscurely.
+//
+// Safe for concurrent use: push takes repo's write lock for its duration.
 func (repo *repo) push(name string, spec refspec, auth auth.Auth) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
 	log.Debugf("{push} %s %s", name, spec.from())
 
+	shallowCommits, err := repo.git.Storer.Shallow()
+	if err != nil {
+		return karma.Format(
+			err,
+			"unable to read shallow commits list",
+		)
+	}
+
+	if repo.shallow.shallow() || len(shallowCommits) > 0 {
+		return karma.Describe("depth", repo.shallow.Depth).Format(
+			nil,
+			"unable to push from a shallow clone; "+
+				"re-clone with CloneOptions.Depth == 0 to unshallow",
+		)
+	}
+
 	method, err := repo.auth(name, auth)
 	if err != nil {
 		return err
@@ -275,10 +827,23 @@ func (repo *repo) push(name string, spec refspec, auth auth.Auth) error {
 	}
 }
 
+// Safe for concurrent use: cat takes repo's read lock for its duration, so
+// it may run concurrently with other list/cat calls but not with a
+// mutating operation.
 func (repo *repo) cat(hash string) ([]byte, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.catLocked(hash)
+}
+
+// catLocked is the shared implementation of cat(), used directly by code
+// that already holds repo.mu (e.g. the clock subsystem, which reads blobs
+// while running under update()/pull()'s write lock).
+func (repo *repo) catLocked(hash string) ([]byte, error) {
 	log.Tracef("{cat} %s ?", hash)
 
-	blob, err := repo.git.BlobObject(git_plumbing.NewHash(hash))
+	object, err := repo.git.BlobObject(git_plumbing.NewHash(hash))
 	if err != nil {
 		return nil, karma.Format(
 			err,
@@ -287,7 +852,7 @@ func (repo *repo) cat(hash string) ([]byte, error) {
 		)
 	}
 
-	reader, err := blob.Reader()
+	reader, err := object.Reader()
 	if err != nil {
 		return nil, karma.Format(
 			err,
@@ -305,11 +870,102 @@ func (repo *repo) cat(hash string) ([]byte, error) {
 		)
 	}
 
+	lfsPointer, isLFSPointer, err := lfs.Decode(data)
+	if err != nil {
+		return nil, karma.Format(
+			err,
+			"unable to decode lfs pointer %q",
+			hash,
+		)
+	}
+
+	if isLFSPointer {
+		data, err = repo.resolveLFSPointer(lfsPointer)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Tracef("{cat} %s = %d bytes", hash, len(data))
+
+		return data, nil
+	}
+
+	pointer, isPointer, err := blob.Decode(data)
+	if err != nil {
+		return nil, karma.Format(
+			err,
+			"unable to decode blob pointer %q",
+			hash,
+		)
+	}
+
+	if isPointer {
+		data, err = repo.resolvePointer(pointer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	log.Tracef("{cat} %s = %d bytes", hash, len(data))
 
 	return data, nil
 }
 
+// resolvePointer fetches the real ciphertext referenced by a pointer blob
+// from its external storage backend. pointer.Backend and pointer.Hash come
+// straight out of a git blob, which may have been pushed by another
+// collaborator (or an attacker in control of the remote), so resolvePointer
+// never trusts them blindly: it only ever talks to the backend the
+// operator configured via UseStorage (refusing to dial out to an arbitrary
+// URL read from the repo), and it requires Hash to be a well-formed hex
+// sha256 digest before it's used as a storage key/path — otherwise a
+// crafted pointer like {"backend":"file:///...","hash":"../../.ssh/id_rsa"}
+// could be used to read arbitrary files off disk through the filesystem
+// backend.
+func (repo *repo) resolvePointer(pointer blob.Pointer) ([]byte, error) {
+	if repo.storage == nil || pointer.Backend != repo.storageURL {
+		return nil, karma.Describe("backend", pointer.Backend).Format(
+			nil,
+			"refusing to resolve blob pointer referencing an unconfigured storage backend",
+		)
+	}
+
+	err := blob.ValidateHash(pointer.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := repo.storage.Get(pointer.Hash)
+	if err != nil {
+		return nil, karma.
+			Describe("backend", pointer.Backend).
+			Describe("hash", pointer.Hash).
+			Format(
+				err,
+				"unable to resolve blob pointer",
+			)
+	}
+
+	return data, nil
+}
+
+// resolveLFSPointer downloads the ciphertext referenced by an LFS pointer
+// blob from the configured LFS server.
+func (repo *repo) resolveLFSPointer(pointer lfs.Pointer) ([]byte, error) {
+	data, err := repo.lfsClient().Download(pointer)
+	if err != nil {
+		return nil, karma.
+			Describe("endpoint", repo.lfs.Endpoint).
+			Describe("oid", pointer.OID).
+			Format(
+				err,
+				"unable to resolve lfs pointer",
+			)
+	}
+
+	return data, nil
+}
+
 func (repo *repo) lock() error {
 	config, err := repo.git.Config()
 	if err != nil {
@@ -0,0 +1,122 @@
+// Package keyring stores the carcosa master passphrase in the host OS
+// credential store (macOS Keychain, Secret Service/KWallet on Linux,
+// Windows Credential Manager, or an encrypted file as a last resort),
+// so users aren't forced to re-enter it on every invocation.
+package keyring
+
+import (
+	"github.com/99designs/keyring"
+	"github.com/reconquest/karma-go"
+)
+
+// Keyring stores and retrieves secrets addressed by a service/key pair,
+// analogous to the stdlib's os.Getenv/os.Setenv but backed by the OS
+// credential store instead of the environment.
+type Keyring interface {
+	Get(service, key string) (string, error)
+	Set(service, key, value string) error
+	Clear(service, key string) error
+}
+
+// Backend names accepted by Open, mirroring the --keyring CLI flag.
+const (
+	BackendAuto          = ""
+	BackendKeychain      = "keychain"
+	BackendSecretService = "secret-service"
+	BackendKWallet       = "kwallet"
+	BackendFile          = "file"
+)
+
+type osKeyring struct {
+	ring keyring.Keyring
+}
+
+// Open opens the requested OS keyring backend. An empty backend name lets
+// the underlying library auto-detect the best available backend for the
+// current platform.
+func Open(backend string) (Keyring, error) {
+	config := keyring.Config{
+		ServiceName: "carcosa",
+	}
+
+	switch backend {
+	case BackendAuto:
+	case BackendKeychain:
+		config.AllowedBackends = []keyring.BackendType{keyring.KeychainBackend}
+	case BackendSecretService:
+		config.AllowedBackends = []keyring.BackendType{keyring.SecretServiceBackend}
+	case BackendKWallet:
+		config.AllowedBackends = []keyring.BackendType{keyring.KWalletBackend}
+	case BackendFile:
+		config.AllowedBackends = []keyring.BackendType{keyring.FileBackend}
+	default:
+		return nil, karma.Describe("backend", backend).Format(
+			nil,
+			"unknown keyring backend",
+		)
+	}
+
+	ring, err := keyring.Open(config)
+	if err != nil {
+		return nil, karma.Describe("backend", backend).Format(
+			err,
+			"unable to open os keyring",
+		)
+	}
+
+	return &osKeyring{ring: ring}, nil
+}
+
+// Key joins a service and key into the single string the underlying
+// keyring library keys items by (service + namespace, e.g. a remote URL
+// combined with a carcosa namespace).
+func Key(service, key string) string {
+	return service + "#" + key
+}
+
+func (kr *osKeyring) Get(service, key string) (string, error) {
+	item, err := kr.ring.Get(Key(service, key))
+	if err != nil {
+		if err == keyring.ErrKeyNotFound {
+			return "", nil
+		}
+
+		return "", karma.Describe("key", key).Format(
+			err,
+			"unable to read keyring item",
+		)
+	}
+
+	return string(item.Data), nil
+}
+
+func (kr *osKeyring) Set(service, key, value string) error {
+	err := kr.ring.Set(keyring.Item{
+		Key:  Key(service, key),
+		Data: []byte(value),
+	})
+	if err != nil {
+		return karma.Describe("key", key).Format(
+			err,
+			"unable to write keyring item",
+		)
+	}
+
+	return nil
+}
+
+func (kr *osKeyring) Clear(service, key string) error {
+	err := kr.ring.Remove(Key(service, key))
+	if err != nil {
+		if err == keyring.ErrKeyNotFound {
+			return nil
+		}
+
+		return karma.Describe("key", key).Format(
+			err,
+			"unable to remove keyring item",
+		)
+	}
+
+	return nil
+}
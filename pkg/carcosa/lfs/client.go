@@ -0,0 +1,218 @@
+package lfs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/reconquest/karma-go"
+	git_http "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+)
+
+// Client talks to a Git LFS server's batch API to upload and download
+// objects, authenticating the same way the carcosa remote itself does.
+type Client struct {
+	// Endpoint is the LFS server root, e.g.
+	// "https://github.com/user/repo.git/info/lfs".
+	Endpoint string
+
+	// Auth carries the HTTP basic-auth credentials already resolved for
+	// the git remote (an auth.Auth provider resolves these the same way
+	// for both git and LFS). LFS batch operations only support the
+	// HTTPS/basic-auth transports carcosa's auth package produces.
+	Auth *git_http.BasicAuth
+}
+
+type batchRequest struct {
+	Operation string        `json:"operation"`
+	Transfers []string      `json:"transfers"`
+	Objects   []batchObject `json:"objects"`
+}
+
+type batchObject struct {
+	OID  string `json:"oid"`
+	Size int    `json:"size"`
+}
+
+type batchResponse struct {
+	Objects []struct {
+		OID     string `json:"oid"`
+		Size    int    `json:"size"`
+		Actions map[string]struct {
+			Href   string            `json:"href"`
+			Header map[string]string `json:"header"`
+		} `json:"actions"`
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	} `json:"objects"`
+}
+
+// Hash returns the sha256 hex digest LFS addresses objects by.
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func (client *Client) batch(operation string, pointer Pointer) (string, map[string]string, error) {
+	body, err := json.Marshal(batchRequest{
+		Operation: operation,
+		Transfers: []string{"basic"},
+		Objects:   []batchObject{{OID: pointer.OID, Size: pointer.Size}},
+	})
+	if err != nil {
+		return "", nil, karma.Format(err, "unable to marshal lfs batch request")
+	}
+
+	request, err := http.NewRequest(
+		"POST", client.Endpoint+"/objects/batch", bytes.NewReader(body),
+	)
+	if err != nil {
+		return "", nil, karma.Format(err, "unable to build lfs batch request")
+	}
+
+	request.Header.Set("Accept", "application/vnd.git-lfs+json")
+	request.Header.Set("Content-Type", "application/vnd.git-lfs+json")
+
+	if client.Auth != nil {
+		request.SetBasicAuth(client.Auth.Username, client.Auth.Password)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return "", nil, karma.Format(err, "unable to perform lfs batch request")
+	}
+	defer response.Body.Close()
+
+	raw, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return "", nil, karma.Format(err, "unable to read lfs batch response")
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return "", nil, karma.
+			Describe("status", response.StatusCode).
+			Format(nil, "lfs batch request failed: %s", string(raw))
+	}
+
+	var parsed batchResponse
+
+	err = json.Unmarshal(raw, &parsed)
+	if err != nil {
+		return "", nil, karma.Format(err, "unable to unmarshal lfs batch response")
+	}
+
+	for _, object := range parsed.Objects {
+		if object.OID != pointer.OID {
+			continue
+		}
+
+		if object.Error != nil {
+			return "", nil, karma.Format(
+				nil,
+				"lfs server rejected oid %q: %s",
+				object.OID, object.Error.Message,
+			)
+		}
+
+		action, ok := object.Actions[operation]
+		if !ok {
+			// No action for this operation: for "upload" it means the
+			// server already has the object; for "download" the empty
+			// href is reported as an error by Download.
+			return "", nil, nil
+		}
+
+		return action.Href, action.Header, nil
+	}
+
+	return "", nil, karma.Format(nil, "lfs server did not return object %q", pointer.OID)
+}
+
+// Upload stores data in the LFS server under its content hash, unless the
+// server reports it already has the object.
+func (client *Client) Upload(data []byte) (Pointer, error) {
+	pointer := Pointer{OID: Hash(data), Size: len(data)}
+
+	href, header, err := client.batch("upload", pointer)
+	if err != nil {
+		return Pointer{}, err
+	}
+
+	if href == "" {
+		return pointer, nil
+	}
+
+	request, err := http.NewRequest("PUT", href, bytes.NewReader(data))
+	if err != nil {
+		return Pointer{}, karma.Format(err, "unable to build lfs upload request")
+	}
+
+	for key, value := range header {
+		request.Header.Set(key, value)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return Pointer{}, karma.Format(err, "unable to upload object to lfs server")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode/100 != 2 {
+		return Pointer{}, karma.Describe("status", response.StatusCode).Format(
+			nil,
+			"lfs server rejected upload of oid %q", pointer.OID,
+		)
+	}
+
+	return pointer, nil
+}
+
+// Download retrieves the object referenced by pointer from the LFS server.
+func (client *Client) Download(pointer Pointer) ([]byte, error) {
+	href, header, err := client.batch("download", pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	if href == "" {
+		return nil, karma.Format(
+			nil,
+			"lfs server has no download action for oid %q", pointer.OID,
+		)
+	}
+
+	request, err := http.NewRequest("GET", href, nil)
+	if err != nil {
+		return nil, karma.Format(err, "unable to build lfs download request")
+	}
+
+	for key, value := range header {
+		request.Header.Set(key, value)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, karma.Format(err, "unable to download object from lfs server")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, karma.Describe("status", response.StatusCode).Format(
+			nil,
+			"lfs server rejected download of oid %q", pointer.OID,
+		)
+	}
+
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, karma.Format(err, "unable to read lfs object body")
+	}
+
+	return data, nil
+}
@@ -0,0 +1,66 @@
+// Package lfs implements enough of the Git LFS pointer format and batch
+// API to offload oversized secret payloads to an LFS server, the same way
+// a real `git-lfs` filter would for a working tree checkout.
+package lfs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/reconquest/karma-go"
+)
+
+// version is the pointer format version carcosa writes and understands,
+// matching the spec at https://git-lfs.github.com/spec/v1.
+const version = "https://git-lfs.github.com/spec/v1"
+
+// Pointer is the small text blob committed to git in place of an
+// oversized secret's ciphertext.
+type Pointer struct {
+	OID  string
+	Size int
+}
+
+// Encode renders the pointer in the canonical Git LFS text format.
+func (pointer Pointer) Encode() []byte {
+	return []byte(fmt.Sprintf(
+		"version %s\noid sha256:%s\nsize %d\n",
+		version, pointer.OID, pointer.Size,
+	))
+}
+
+// Decode recognizes an LFS pointer blob, returning ok == false if data is
+// not one (i.e. it is inline ciphertext or a blob.Pointer instead).
+func Decode(data []byte) (pointer Pointer, ok bool, err error) {
+	text := string(data)
+	if !strings.HasPrefix(text, "version "+version+"\n") {
+		return Pointer{}, false, nil
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		switch {
+		case strings.HasPrefix(line, "oid sha256:"):
+			pointer.OID = strings.TrimPrefix(line, "oid sha256:")
+		case strings.HasPrefix(line, "size "):
+			size, err := strconv.Atoi(strings.TrimPrefix(line, "size "))
+			if err != nil {
+				return Pointer{}, true, karma.Format(
+					err,
+					"unable to parse lfs pointer size %q", line,
+				)
+			}
+
+			pointer.Size = size
+		}
+	}
+
+	if pointer.OID == "" {
+		return Pointer{}, true, karma.Format(
+			nil,
+			"lfs pointer is missing an oid",
+		)
+	}
+
+	return pointer, true, nil
+}
@@ -0,0 +1,49 @@
+package lfs
+
+import "testing"
+
+func TestPointerEncodeDecodeRoundTrip(t *testing.T) {
+	pointer := Pointer{
+		OID:  "4e07408562bedb8b60ce05c1decfe3ad16b72230967de01f640b7e4729b49fc",
+		Size: 4096,
+	}
+
+	encoded := pointer.Encode()
+
+	decoded, ok, err := Decode(encoded)
+	if err != nil {
+		t.Fatalf("unexpected error decoding pointer: %s", err)
+	}
+
+	if !ok {
+		t.Fatalf("expected Decode to recognize an lfs pointer blob")
+	}
+
+	if decoded != pointer {
+		t.Fatalf("expected decoded pointer %+v to equal original %+v", decoded, pointer)
+	}
+}
+
+func TestDecodeRejectsNonPointerData(t *testing.T) {
+	_, ok, err := Decode([]byte("not an lfs pointer"))
+	if err != nil {
+		t.Fatalf("unexpected error decoding non-pointer data: %s", err)
+	}
+
+	if ok {
+		t.Fatalf("expected Decode to report ok == false for non-pointer data")
+	}
+}
+
+func TestDecodeRejectsMissingOID(t *testing.T) {
+	data := []byte("version " + version + "\nsize 10\n")
+
+	_, ok, err := Decode(data)
+	if !ok {
+		t.Fatalf("expected Decode to recognize the pointer header even without an oid")
+	}
+
+	if err == nil {
+		t.Fatalf("expected an error for a pointer blob missing its oid")
+	}
+}